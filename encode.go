@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"text/template"
+	"time"
+)
+
+// Encoder writes a result set to w in some output format.
+type Encoder interface {
+	Encode(w io.Writer, files []FileInfo) error
+}
+
+// StreamEncoder is implemented by encoders that can write each result as
+// it's received rather than requiring the full, sorted result set up
+// front. newestfiles uses it to feed -0 and -ndjson straight from
+// ScanStream when no sort order was requested, so output starts before
+// the walk finishes and memory never holds more than one result at a time.
+type StreamEncoder interface {
+	EncodeStream(w io.Writer, matches <-chan FileInfo) error
+}
+
+// plainEncoder writes one path per line; it's the tool's original default.
+type plainEncoder struct{}
+
+func (plainEncoder) Encode(w io.Writer, files []FileInfo) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range files {
+		fmt.Fprintln(bw, f.Path)
+	}
+	return bw.Flush()
+}
+
+// jsonEncoder writes the full result set as a single JSON array of paths.
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(w io.Writer, files []FileInfo) error {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	out, err := json.Marshal(paths)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// nulEncoder writes each path followed by a NUL byte, safe for piping into
+// "xargs -0" even when paths contain newlines.
+type nulEncoder struct{}
+
+func (e nulEncoder) Encode(w io.Writer, files []FileInfo) error {
+	return e.EncodeStream(w, toChannel(files))
+}
+
+func (nulEncoder) EncodeStream(w io.Writer, matches <-chan FileInfo) error {
+	bw := bufio.NewWriter(w)
+	for f := range matches {
+		if _, err := bw.WriteString(f.Path); err != nil {
+			return err
+		}
+		if err := bw.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ndjsonEncoder writes one JSON object per line with path, size and mtime,
+// plus a sha256 checksum when hash is set. fsys is read to compute the
+// checksum, so it must be the same fs.FS the paths were scanned from.
+type ndjsonEncoder struct {
+	fsys fs.FS
+	hash bool
+}
+
+type ndjsonRecord struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mtime"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+func (e ndjsonEncoder) Encode(w io.Writer, files []FileInfo) error {
+	return e.EncodeStream(w, toChannel(files))
+}
+
+func (e ndjsonEncoder) EncodeStream(w io.Writer, matches <-chan FileInfo) error {
+	enc := json.NewEncoder(w)
+	for f := range matches {
+		rec := ndjsonRecord{
+			Path:    f.Path,
+			Size:    f.Size,
+			ModTime: f.ModTime.Format(time.RFC3339),
+		}
+		if e.hash {
+			sum, err := fileSHA256(e.fsys, f.Path)
+			if err != nil {
+				return err
+			}
+			rec.SHA256 = sum
+		}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toChannel adapts an already-collected slice to the channel shape
+// EncodeStream expects, for callers (and the non-streaming Encode methods
+// above) that only have a slice to hand it.
+func toChannel(files []FileInfo) <-chan FileInfo {
+	ch := make(chan FileInfo, len(files))
+	for _, f := range files {
+		ch <- f
+	}
+	close(ch)
+	return ch
+}
+
+// fileSHA256 reads path from fsys, the same fs.FS the scan ran against, so
+// -hash reports the checksum of what was actually scanned rather than
+// whatever happens to be on local disk at that path.
+func fileSHA256(fsys fs.FS, path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// templateEncoder renders each file through a text/template, one execution
+// per file, separated by newlines. Templates see .Path, .Size, .ModTime,
+// plus the humanSize and relTime helper functions.
+type templateEncoder struct {
+	tmpl *template.Template
+}
+
+func newTemplateEncoder(text string) (*templateEncoder, error) {
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"humanSize": humanSize,
+		"relTime":   relTime,
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &templateEncoder{tmpl: tmpl}, nil
+}
+
+func (e *templateEncoder) Encode(w io.Writer, files []FileInfo) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range files {
+		if err := e.tmpl.Execute(bw, f); err != nil {
+			return err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// humanSize formats a byte count like "1.5K", "3.2M", "1.0G".
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// relTime formats t relative to now, e.g. "3h ago".
+func relTime(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
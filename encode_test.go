@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func sampleFiles() []FileInfo {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []FileInfo{
+		{Path: "a.go", Size: 100, ModTime: now},
+		{Path: "b.txt", Size: 2048, ModTime: now.Add(-time.Hour)},
+	}
+}
+
+func TestNulEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (nulEncoder{}).Encode(&buf, sampleFiles()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	parts := strings.Split(strings.TrimRight(buf.String(), "\x00"), "\x00")
+	if len(parts) != 2 || parts[0] != "a.go" || parts[1] != "b.txt" {
+		t.Fatalf("unexpected NUL-separated output: %q", buf.String())
+	}
+}
+
+func TestNdjsonEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (ndjsonEncoder{}).Encode(&buf, sampleFiles()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	var rec ndjsonRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("failed to parse ndjson line: %v", err)
+	}
+	if rec.Path != "a.go" || rec.Size != 100 || rec.SHA256 != "" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestNdjsonEncoderHashReadsFromFsys(t *testing.T) {
+	data := []byte("package main\n")
+	fsys := fstest.MapFS{
+		"a.go": &fstest.MapFile{Data: data},
+	}
+	sum := sha256.Sum256(data)
+	want := hex.EncodeToString(sum[:])
+
+	var buf bytes.Buffer
+	enc := ndjsonEncoder{fsys: fsys, hash: true}
+	if err := enc.Encode(&buf, []FileInfo{{Path: "a.go", Size: int64(len(data))}}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var rec ndjsonRecord
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("failed to parse ndjson line: %v", err)
+	}
+	if rec.SHA256 != want {
+		t.Errorf("SHA256 = %q, want %q (hashed from the scanned fs.FS, not local disk)", rec.SHA256, want)
+	}
+}
+
+func TestNulEncoderStream(t *testing.T) {
+	ch := make(chan FileInfo, 2)
+	for _, f := range sampleFiles() {
+		ch <- f
+	}
+	close(ch)
+
+	var buf bytes.Buffer
+	if err := (nulEncoder{}).EncodeStream(&buf, ch); err != nil {
+		t.Fatalf("EncodeStream: %v", err)
+	}
+	parts := strings.Split(strings.TrimRight(buf.String(), "\x00"), "\x00")
+	if len(parts) != 2 || parts[0] != "a.go" || parts[1] != "b.txt" {
+		t.Fatalf("unexpected NUL-separated output: %q", buf.String())
+	}
+}
+
+func TestNdjsonEncoderStream(t *testing.T) {
+	ch := make(chan FileInfo, 2)
+	for _, f := range sampleFiles() {
+		ch <- f
+	}
+	close(ch)
+
+	var buf bytes.Buffer
+	if err := (ndjsonEncoder{}).EncodeStream(&buf, ch); err != nil {
+		t.Fatalf("EncodeStream: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+}
+
+func TestTemplateEncoder(t *testing.T) {
+	enc, err := newTemplateEncoder("{{.Path}} {{humanSize .Size}}")
+	if err != nil {
+		t.Fatalf("newTemplateEncoder: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, sampleFiles()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "a.go 100B") || !strings.Contains(got, "b.txt 2.0K") {
+		t.Errorf("unexpected template output: %q", got)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	cases := map[int64]string{
+		500:             "500B",
+		2048:            "2.0K",
+		5 * 1024 * 1024: "5.0M",
+	}
+	for size, want := range cases {
+		if got := humanSize(size); got != want {
+			t.Errorf("humanSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
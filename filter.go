@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate reports whether a file, identified by the path Scan walked to
+// and its info, should be included in the results.
+type Predicate func(path string, info fs.FileInfo) bool
+
+// andPredicates combines predicates with a logical AND. Nil predicates are
+// ignored; an all-nil or empty slice matches everything.
+func andPredicates(preds ...Predicate) Predicate {
+	var active []Predicate
+	for _, p := range preds {
+		if p != nil {
+			active = append(active, p)
+		}
+	}
+	return func(path string, info fs.FileInfo) bool {
+		for _, p := range active {
+			if !p(path, info) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// orPredicates combines predicates with a logical OR. An empty slice always
+// matches, since it represents "no name filter requested".
+func orPredicates(preds ...Predicate) Predicate {
+	if len(preds) == 0 {
+		return func(string, fs.FileInfo) bool { return true }
+	}
+	return func(path string, info fs.FileInfo) bool {
+		for _, p := range preds {
+			if p(path, info) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// suffixPredicate matches a file's base name against suffixes, case
+// insensitively. An empty suffixes slice matches everything.
+func suffixPredicate(suffixes []string) Predicate {
+	if len(suffixes) == 0 {
+		return func(string, fs.FileInfo) bool { return true }
+	}
+	lower := make([]string, len(suffixes))
+	for i, s := range suffixes {
+		lower[i] = strings.ToLower(s)
+	}
+	return func(path string, info fs.FileInfo) bool {
+		name := strings.ToLower(info.Name())
+		for _, suffix := range lower {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// nameGlobPredicate matches the full path against a shell glob pattern.
+// "**" matches any number of path segments, including none; a bare "*"
+// does not cross a "/".
+func nameGlobPredicate(pattern string, foldCase bool) (Predicate, error) {
+	re, err := globToRegexp(pattern, foldCase)
+	if err != nil {
+		return nil, err
+	}
+	return func(path string, info fs.FileInfo) bool {
+		return re.MatchString(path)
+	}, nil
+}
+
+// regexPredicate matches the full path against a Go regexp.
+func regexPredicate(expr string) (Predicate, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return func(path string, info fs.FileInfo) bool {
+		return re.MatchString(path)
+	}, nil
+}
+
+// newerPredicate matches files modified after ref.
+func newerPredicate(ref time.Time) Predicate {
+	return func(path string, info fs.FileInfo) bool {
+		return info.ModTime().After(ref)
+	}
+}
+
+// mtimePredicate implements find-style "-mtime +/-N": sign '+' matches files
+// last modified more than d ago, '-' matches files modified less than d ago.
+func mtimePredicate(sign byte, d time.Duration) Predicate {
+	return func(path string, info fs.FileInfo) bool {
+		age := time.Since(info.ModTime())
+		if sign == '-' {
+			return age < d
+		}
+		return age > d
+	}
+}
+
+// sizePredicate implements find-style "-size +/-N": sign '+' matches files
+// larger than n bytes, '-' matches files smaller than n bytes.
+func sizePredicate(sign byte, n int64) Predicate {
+	return func(path string, info fs.FileInfo) bool {
+		if sign == '-' {
+			return info.Size() < n
+		}
+		return info.Size() > n
+	}
+}
+
+// typePredicate matches files by kind: 'f' regular, 'd' directory, 'l' symlink.
+func typePredicate(kind byte) Predicate {
+	return func(path string, info fs.FileInfo) bool {
+		switch kind {
+		case 'f':
+			return info.Mode().IsRegular()
+		case 'd':
+			return info.IsDir()
+		case 'l':
+			return info.Mode()&fs.ModeSymlink != 0
+		default:
+			return false
+		}
+	}
+}
+
+// globToRegexp translates a shell glob pattern into an anchored regexp.
+// "**" matches across path separators; "*" and "?" do not.
+func globToRegexp(pattern string, foldCase bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+2 < len(pattern) && pattern[i+1] == '*' && pattern[i+2] == '/':
+			// "**/" matches zero or more leading path segments, so
+			// "**/*.go" also matches "a.go" at the root.
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	expr := b.String()
+	if foldCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// parseSignedDuration parses a find-style spec like "+7d" or "-90m" into a
+// sign ('+' or '-', defaulting to '+') and a duration. It extends
+// time.ParseDuration with a "d" (day) unit.
+func parseSignedDuration(spec string) (byte, time.Duration, error) {
+	sign := byte('+')
+	switch {
+	case strings.HasPrefix(spec, "+"):
+		spec = spec[1:]
+	case strings.HasPrefix(spec, "-"):
+		sign = '-'
+		spec = spec[1:]
+	}
+	if strings.HasSuffix(spec, "d") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(spec, "d"), 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q: %w", spec, err)
+		}
+		return sign, time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(spec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid duration %q: %w", spec, err)
+	}
+	return sign, d, nil
+}
+
+// parseSignedSize parses a find-style spec like "+10M" or "-1k" into a sign
+// ('+' or '-', defaulting to '+') and a byte count. Supported suffixes are
+// k/K, m/M, g/G (base 1024); no suffix means bytes.
+func parseSignedSize(spec string) (byte, int64, error) {
+	sign := byte('+')
+	switch {
+	case strings.HasPrefix(spec, "+"):
+		spec = spec[1:]
+	case strings.HasPrefix(spec, "-"):
+		sign = '-'
+		spec = spec[1:]
+	}
+
+	mult := int64(1)
+	if len(spec) > 0 {
+		switch spec[len(spec)-1] {
+		case 'k', 'K':
+			mult = 1 << 10
+			spec = spec[:len(spec)-1]
+		case 'm', 'M':
+			mult = 1 << 20
+			spec = spec[:len(spec)-1]
+		case 'g', 'G':
+			mult = 1 << 30
+			spec = spec[:len(spec)-1]
+		}
+	}
+	n, err := strconv.ParseInt(spec, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid size %q: %w", spec, err)
+	}
+	return sign, n * mult, nil
+}
+
+// buildFilter assembles the single Predicate Scan is given from the CLI's
+// suffix arguments and the explicit filter flags. The suffix arguments
+// (matched case-insensitively against the base name, like -iname
+// "**/*.SUFFIX"), -name and -iname are OR'd together into one name match;
+// the remaining filter flags are AND'd with the name match and each other.
+func buildFilter(suffixes []string, name, iname, regex, newer, mtime, size, typ string, exclude []string) (Predicate, error) {
+	var nameFilters []Predicate
+	if len(suffixes) > 0 {
+		nameFilters = append(nameFilters, suffixPredicate(suffixes))
+	}
+	if name != "" {
+		p, err := nameGlobPredicate(name, false)
+		if err != nil {
+			return nil, fmt.Errorf("-name: %w", err)
+		}
+		nameFilters = append(nameFilters, p)
+	}
+	if iname != "" {
+		p, err := nameGlobPredicate(iname, true)
+		if err != nil {
+			return nil, fmt.Errorf("-iname: %w", err)
+		}
+		nameFilters = append(nameFilters, p)
+	}
+
+	var preds []Predicate
+	if len(nameFilters) > 0 {
+		preds = append(preds, orPredicates(nameFilters...))
+	}
+
+	if regex != "" {
+		p, err := regexPredicate(regex)
+		if err != nil {
+			return nil, fmt.Errorf("-regex: %w", err)
+		}
+		preds = append(preds, p)
+	}
+
+	if newer != "" {
+		info, err := os.Stat(newer)
+		if err != nil {
+			return nil, fmt.Errorf("-newer: %w", err)
+		}
+		preds = append(preds, newerPredicate(info.ModTime()))
+	}
+
+	if mtime != "" {
+		sign, d, err := parseSignedDuration(mtime)
+		if err != nil {
+			return nil, fmt.Errorf("-mtime: %w", err)
+		}
+		preds = append(preds, mtimePredicate(sign, d))
+	}
+
+	if size != "" {
+		sign, n, err := parseSignedSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("-size: %w", err)
+		}
+		preds = append(preds, sizePredicate(sign, n))
+	}
+
+	if typ != "" {
+		if len(typ) != 1 {
+			return nil, fmt.Errorf("-type: expected a single letter (f, d, or l), got %q", typ)
+		}
+		preds = append(preds, typePredicate(typ[0]))
+	}
+
+	for _, glob := range exclude {
+		excl, err := nameGlobPredicate(glob, false)
+		if err != nil {
+			return nil, fmt.Errorf("-exclude: %w", err)
+		}
+		preds = append(preds, func(path string, info fs.FileInfo) bool {
+			return !excl(path, info)
+		})
+	}
+
+	return andPredicates(preds...), nil
+}
+
+// stringList accumulates repeated occurrences of a flag, e.g. "-exclude"
+// passed more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
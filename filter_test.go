@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNameGlobDoublestar(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go":          &fstest.MapFile{Data: []byte("a")},
+		"sub/b.go":      &fstest.MapFile{Data: []byte("b")},
+		"sub/deep/c.go": &fstest.MapFile{Data: []byte("c")},
+		"sub/d.txt":     &fstest.MapFile{Data: []byte("d")},
+	}
+
+	filter, err := nameGlobPredicate("**/*.go", false)
+	if err != nil {
+		t.Fatalf("nameGlobPredicate: %v", err)
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 .go files under **, got %d: %v", len(files), files)
+	}
+}
+
+func TestBuildFilterExcludeGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"keep.go":        &fstest.MapFile{Data: []byte("a")},
+		"vendor/skip.go": &fstest.MapFile{Data: []byte("b")},
+	}
+
+	filter, err := buildFilter(nil, "", "", "", "", "", "", "", []string{"vendor/**"})
+	if err != nil {
+		t.Fatalf("buildFilter: %v", err)
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "keep.go" {
+		t.Fatalf("expected only keep.go, got %v", files)
+	}
+}
+
+func TestSizePredicate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"small.txt": &fstest.MapFile{Data: make([]byte, 10)},
+		"big.txt":   &fstest.MapFile{Data: make([]byte, 2048)},
+	}
+
+	_, bytes, err := parseSignedSize("+1k")
+	if err != nil {
+		t.Fatalf("parseSignedSize: %v", err)
+	}
+	filter := sizePredicate('+', bytes)
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "big.txt" {
+		t.Fatalf("expected only big.txt, got %v", files)
+	}
+}
+
+func TestTypePredicateDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sub/nested.go": &fstest.MapFile{Data: []byte("a")},
+		"top.go":        &fstest.MapFile{Data: []byte("b")},
+	}
+
+	filter := typePredicate('d')
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: filter, IncludeDirs: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "sub" {
+		t.Fatalf("expected only the \"sub\" directory, got %v", files)
+	}
+}
+
+func TestMtimePredicate(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"recent.txt": &fstest.MapFile{ModTime: now},
+		"old.txt":    &fstest.MapFile{ModTime: now.Add(-48 * time.Hour)},
+	}
+
+	sign, d, err := parseSignedDuration("+1d")
+	if err != nil {
+		t.Fatalf("parseSignedDuration: %v", err)
+	}
+	filter := mtimePredicate(sign, d)
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: filter})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "old.txt" {
+		t.Fatalf("expected only old.txt, got %v", files)
+	}
+}
@@ -1,29 +1,35 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
-	"time"
 )
 
-type FileInfo struct {
-	Path    string
-	ModTime time.Time
-	Size    int64
-}
-
 func main() {
 	// Define command line flags
 	jsonOutput := flag.Bool("j", false, "output in JSON format")
 	oldest := flag.Bool("o", false, "Sort oldest to newest")
 	largest := flag.Bool("l", false, "Sort by largest files first")
 	smallest := flag.Bool("s", false, "Sort by smallest files first")
+	workers := flag.Int("P", 0, "number of directories to read concurrently (default: number of CPUs)")
+	maxDepth := flag.Int("max-depth", 0, "maximum directory depth to descend into (0 = unlimited)")
+	name := flag.String("name", "", "match files whose path fits GLOB (** matches across directories)")
+	iname := flag.String("iname", "", "like -name, but case-insensitive")
+	regex := flag.String("regex", "", "match files whose path fits the regular expression RE")
+	newer := flag.String("newer", "", "match files modified more recently than FILE")
+	mtime := flag.String("mtime", "", "match files last modified +/-DURATION ago, e.g. -7d, +1h")
+	size := flag.String("size", "", "match files +/-SIZE, e.g. +10M, -1k")
+	typeFlag := flag.String("type", "", "match files of TYPE: f (regular), d (directory), l (symlink)")
+	topN := flag.Int("n", 0, "only return the top N results (0 = return all)")
+	nulOutput := flag.Bool("0", false, "output NUL-separated paths, safe for xargs -0")
+	ndjsonOutput := flag.Bool("ndjson", false, "output one JSON object per line with path, size and mtime")
+	hashOutput := flag.Bool("hash", false, "include a sha256 checksum in -ndjson output")
+	format := flag.String("format", "", "render each result through this text/template instead of a built-in format")
+	var exclude stringList
+	flag.Var(&exclude, "exclude", "exclude files whose path fits GLOB (repeatable)")
 	flag.Parse()
 
 	// Get suffix arguments from command line (after flags)
@@ -45,6 +51,25 @@ func main() {
 		return
 	}
 
+	// Check for conflicting output format flags
+	formatFlags := 0
+	if *jsonOutput {
+		formatFlags++
+	}
+	if *nulOutput {
+		formatFlags++
+	}
+	if *ndjsonOutput {
+		formatFlags++
+	}
+	if *format != "" {
+		formatFlags++
+	}
+	if formatFlags > 1 {
+		fmt.Println("Error: Only one output format can be specified at a time")
+		return
+	}
+
 	// Normalize suffixes to ensure they start with a dot (if any suffixes provided)
 	for i, suffix := range suffixes {
 		if !strings.HasPrefix(suffix, ".") {
@@ -52,45 +77,72 @@ func main() {
 		}
 	}
 
-	var files []FileInfo
+	filter, err := buildFilter(suffixes, *name, *iname, *regex, *newer, *mtime, *size, *typeFlag, exclude)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 
-	// Walk through current directory and subdirectories
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+	scanOpts := ScanOptions{
+		Filter:      filter,
+		Workers:     *workers,
+		MaxDepth:    *maxDepth,
+		IncludeDirs: *typeFlag == "d",
+	}
+
+	// Pick the encoder for the selected output format.
+	var enc Encoder
+	switch {
+	case *format != "":
+		e, err := newTemplateEncoder(*format)
 		if err != nil {
-			log.Printf("Error accessing %s: %v\n", path, err)
-			return nil // Continue walking despite errors
+			fmt.Printf("Error: invalid -format template: %v\n", err)
+			return
 		}
+		enc = e
+	case *ndjsonOutput:
+		enc = ndjsonEncoder{fsys: os.DirFS("."), hash: *hashOutput}
+	case *nulOutput:
+		enc = nulEncoder{}
+	case *jsonOutput:
+		enc = jsonEncoder{}
+	default:
+		enc = plainEncoder{}
+	}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
+	sortRequested := *oldest || *largest || *smallest
 
-		// Check if file has one of the target suffixes, or include all files if no suffixes specified
-		if len(suffixes) == 0 {
-			// No suffixes specified, include all files
-			files = append(files, FileInfo{
-				Path:    path,
-				ModTime: info.ModTime(),
-				Size:    info.Size(),
-			})
-		} else {
-			// Check if file has one of the target suffixes
-			for _, suffix := range suffixes {
-				if strings.HasSuffix(strings.ToLower(info.Name()), strings.ToLower(suffix)) {
-					files = append(files, FileInfo{
-						Path:    path,
-						ModTime: info.ModTime(),
-						Size:    info.Size(),
-					})
-					break // Found a match, no need to check other suffixes
-				}
-			}
+	// A StreamEncoder can write results as the walker finds them, with no
+	// buffering and no wait for the walk to finish, but only when nothing
+	// downstream needs the full set first: -n needs every match to pick
+	// the top N, and a requested sort order needs every match to know
+	// what comes first.
+	if streamer, ok := enc.(StreamEncoder); ok && *topN == 0 && !sortRequested {
+		matches, errc := ScanStream(os.DirFS("."), []string{"."}, scanOpts)
+		if err := streamer.EncodeStream(os.Stdout, matches); err != nil {
+			log.Printf("Error writing output: %v\n", err)
+			return
+		}
+		if err := <-errc; err != nil {
+			log.Printf("Error walking directory: %v\n", err)
 		}
+		return
+	}
 
-		return nil
-	})
+	// Sort files based on the selected option. When -n is given, a bounded
+	// heap is fed during the walk so only the top N are ever held in
+	// memory, instead of collecting every match and sorting the full set.
+	less := sortLess(*oldest, *largest, *smallest)
 
+	var files []FileInfo
+	if *topN > 0 {
+		files, err = ScanTopK(os.DirFS("."), []string{"."}, scanOpts, *topN, less)
+	} else {
+		files, err = Scan(os.DirFS("."), []string{"."}, scanOpts)
+		if err == nil {
+			sortByLess(files, less)
+		}
+	}
 	if err != nil {
 		log.Printf("Error walking directory: %v\n", err)
 		return
@@ -105,42 +157,7 @@ func main() {
 		return
 	}
 
-	// Sort files based on the selected option
-	if *oldest {
-		// Sort oldest to newest (ascending by modification time)
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].ModTime.Before(files[j].ModTime)
-		})
-	} else if *largest {
-		// Sort by largest files first (descending by size)
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].Size > files[j].Size
-		})
-	} else if *smallest {
-		// Sort by smallest files first (ascending by size)
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].Size < files[j].Size
-		})
-	} else {
-		// Default: Sort by newest first (descending by modification time)
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].ModTime.After(files[j].ModTime)
-		})
-	}
-
-	// Output the sorted list
-	if *jsonOutput {
-		// JSON output
-		var fns []string
-		for _, file := range files {
-			fns = append(fns, file.Path)
-		}
-		out, _ := json.Marshal(&fns)
-		fmt.Printf("%s", out)
-	} else {
-		// Plain text output (default)
-		for _, file := range files {
-			fmt.Println(file.Path)
-		}
+	if err := enc.Encode(os.Stdout, files); err != nil {
+		log.Printf("Error writing output: %v\n", err)
 	}
 }
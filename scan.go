@@ -0,0 +1,260 @@
+package main
+
+import (
+	"io/fs"
+	"log"
+	"path"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// FileInfo is the subset of file metadata newestfiles sorts and prints on.
+type FileInfo struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+}
+
+// ScanOptions controls which files Scan returns and how it walks the tree.
+type ScanOptions struct {
+	// Filter is applied to every file and symlink found (and to every
+	// directory when IncludeDirs is set); only matches are returned. A nil
+	// Filter matches everything.
+	Filter Predicate
+
+	// Workers is the number of directories read concurrently. Zero means
+	// runtime.NumCPU().
+	Workers int
+
+	// MaxDepth limits how many directory levels below each root are
+	// descended into. Zero means unlimited.
+	MaxDepth int
+
+	// IncludeDirs also passes directory entries to Filter, so "-type d"
+	// has something to match. Off by default, since most callers only
+	// want the files.
+	IncludeDirs bool
+}
+
+// dirJob is one directory queued for a worker to read.
+type dirJob struct {
+	path  string
+	depth int
+}
+
+// dirQueue is an unbounded FIFO of dirJob shared by the worker pool. Unlike
+// a buffered channel, push never blocks, so a worker discovering a large
+// directory can enqueue every subdirectory in it without spawning a
+// goroutine per entry just to avoid filling up a fixed-size channel.
+type dirQueue struct {
+	mu     sync.Mutex
+	cond   sync.Cond
+	items  []dirJob
+	closed bool
+}
+
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.cond.L = &q.mu
+	return q
+}
+
+func (q *dirQueue) push(job dirJob) {
+	q.mu.Lock()
+	q.items = append(q.items, job)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop blocks until a job is available or the queue is closed, in which case
+// it returns false.
+func (q *dirQueue) pop() (dirJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return dirJob{}, false
+	}
+	job := q.items[0]
+	q.items = q.items[1:]
+	return job, true
+}
+
+// close wakes every worker blocked in pop once no more jobs will be pushed.
+func (q *dirQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// Scan walks roots within fsys and returns every file and symlink (and, with
+// opts.IncludeDirs, every directory) matching opts.
+//
+// fsys is an fs.FS rather than the OS directly so callers can point the scan
+// at an in-memory tree (fstest.MapFS) in tests, or at any other fs.FS-backed
+// source, without touching disk.
+//
+// Directories are read concurrently by a small worker pool instead of a
+// single-threaded walk, since stat-ing every entry in a large tree is
+// dominated by I/O latency rather than CPU.
+func Scan(fsys fs.FS, roots []string, opts ScanOptions) ([]FileInfo, error) {
+	var (
+		mu    sync.Mutex
+		files []FileInfo
+	)
+	err := scanMatches(fsys, roots, opts, func(p string, info fs.FileInfo) {
+		mu.Lock()
+		files = append(files, FileInfo{
+			Path:    p,
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		})
+		mu.Unlock()
+	})
+	return files, err
+}
+
+// ScanStream walks roots like Scan, but sends each match on the returned
+// channel as soon as a worker finds it instead of collecting them into a
+// slice, so a streaming Encoder can start writing before the walk
+// finishes. Once the walk is done, its result (nil on success) is sent on
+// the buffered errc, and then both channels are closed.
+//
+// Since matches arrive in whatever order workers find them, callers that
+// need a specific sort order can't use ScanStream.
+func ScanStream(fsys fs.FS, roots []string, opts ScanOptions) (matches <-chan FileInfo, errc <-chan error) {
+	out := make(chan FileInfo)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer close(errs)
+		errs <- scanMatches(fsys, roots, opts, func(p string, info fs.FileInfo) {
+			out <- FileInfo{Path: p, ModTime: info.ModTime(), Size: info.Size()}
+		})
+	}()
+	return out, errs
+}
+
+// scanMatches is the walk-and-filter core shared by Scan and ScanTopK: it
+// walks roots within fsys and calls onMatch for every entry that passes
+// opts.Filter, leaving it up to the caller to decide how matches are
+// collected (a plain slice for Scan, a bounded heap for ScanTopK).
+//
+// onMatch is called concurrently from multiple goroutines; callers must
+// guard any shared state it touches.
+func scanMatches(fsys fs.FS, roots []string, opts ScanOptions, onMatch func(path string, info fs.FileInfo)) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	filter := opts.Filter
+	if filter == nil {
+		filter = func(string, fs.FileInfo) bool { return true }
+	}
+
+	for _, root := range roots {
+		if err := walkConcurrent(fsys, root, workers, opts.MaxDepth, opts.IncludeDirs, func(p string, info fs.FileInfo) {
+			if filter(p, info) {
+				onMatch(p, info)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkConcurrent reads root and every directory beneath it (up to maxDepth
+// levels, or unlimited when maxDepth is zero) using a pool of workers, and
+// calls visit for each regular file found, and for each directory found when
+// includeDirs is set. Symlinks are never recursed into, so a cycle in the
+// tree can't send a worker into an infinite fan-out; the symlink entry
+// itself is still visited, whether or not it points at a directory.
+//
+// visit is called concurrently from multiple goroutines; callers must guard
+// any shared state it touches.
+func walkConcurrent(fsys fs.FS, root string, workers, maxDepth int, includeDirs bool, visit func(path string, info fs.FileInfo)) error {
+	jobs := newDirQueue()
+	var pending sync.WaitGroup
+
+	var workerWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workerWG.Add(1)
+		go func() {
+			defer workerWG.Done()
+			for {
+				job, ok := jobs.pop()
+				if !ok {
+					return
+				}
+				entries, err := fs.ReadDir(fsys, job.path)
+				if err != nil {
+					log.Printf("Error accessing %s: %v\n", job.path, err)
+					pending.Done()
+					continue
+				}
+				for _, entry := range entries {
+					childPath := job.path
+					if childPath == "." {
+						childPath = entry.Name()
+					} else {
+						childPath = path.Join(childPath, entry.Name())
+					}
+
+					if entry.Type()&fs.ModeSymlink != 0 {
+						// Symlinks are never recursed into, regardless of
+						// what they point to, so a cycle can't send a
+						// worker into an infinite fan-out. The symlink
+						// entry itself is still visited, so "-type l"
+						// matches it whether or not it points at a
+						// directory.
+						info, err := entry.Info()
+						if err != nil {
+							log.Printf("Error accessing %s: %v\n", childPath, err)
+							continue
+						}
+						visit(childPath, info)
+						continue
+					}
+
+					if entry.IsDir() {
+						if includeDirs {
+							if info, err := entry.Info(); err != nil {
+								log.Printf("Error accessing %s: %v\n", childPath, err)
+							} else {
+								visit(childPath, info)
+							}
+						}
+						if maxDepth > 0 && job.depth >= maxDepth {
+							continue
+						}
+						pending.Add(1)
+						jobs.push(dirJob{path: childPath, depth: job.depth + 1})
+						continue
+					}
+
+					info, err := entry.Info()
+					if err != nil {
+						log.Printf("Error accessing %s: %v\n", childPath, err)
+						continue
+					}
+					visit(childPath, info)
+				}
+				pending.Done()
+			}
+		}()
+	}
+
+	pending.Add(1)
+	jobs.push(dirJob{path: root, depth: 0})
+
+	pending.Wait()
+	jobs.close()
+	workerWG.Wait()
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestScanSuffixFilter(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"newest.go":  &fstest.MapFile{Data: []byte("package main"), ModTime: now},
+		"middle.txt": &fstest.MapFile{Data: []byte("hello"), ModTime: now.Add(-time.Hour)},
+		"other.py":   &fstest.MapFile{Data: []byte("print()"), ModTime: now.Add(-30 * time.Minute)},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: suffixPredicate([]string{".go", ".txt"})})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d: %v", len(files), files)
+	}
+}
+
+func TestScanNoSuffixesMatchesEverything(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go":  &fstest.MapFile{Data: []byte("a")},
+		"b.txt": &fstest.MapFile{Data: []byte("b")},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+}
+
+func TestScanPreservesSizeAndModTime(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"big.bin": &fstest.MapFile{Data: make([]byte, 1024), ModTime: now},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	if files[0].Size != 1024 {
+		t.Errorf("expected size 1024, got %d", files[0].Size)
+	}
+	if !files[0].ModTime.Equal(now) {
+		t.Errorf("expected modtime %v, got %v", now, files[0].ModTime)
+	}
+}
+
+func TestScanMaxDepth(t *testing.T) {
+	fsys := fstest.MapFS{
+		"top.go":          &fstest.MapFile{Data: []byte("a")},
+		"sub/nested.go":   &fstest.MapFile{Data: []byte("b")},
+		"sub/deep/far.go": &fstest.MapFile{Data: []byte("c")},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files within max-depth 1, got %d: %v", len(files), files)
+	}
+}
+
+func TestScanStreamMatchesScan(t *testing.T) {
+	fsys := fstest.MapFS{
+		"top.go":          &fstest.MapFile{Data: []byte("a")},
+		"sub/nested.go":   &fstest.MapFile{Data: []byte("b")},
+		"sub/deep/far.go": &fstest.MapFile{Data: []byte("c")},
+	}
+
+	matches, errc := ScanStream(fsys, []string{"."}, ScanOptions{})
+	seen := map[string]bool{}
+	for f := range matches {
+		seen[f.Path] = true
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("ScanStream returned error: %v", err)
+	}
+
+	want, err := Scan(fsys, []string{"."}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %d streamed matches, got %d: %v", len(want), len(seen), seen)
+	}
+	for _, f := range want {
+		if !seen[f.Path] {
+			t.Errorf("expected ScanStream to include %q", f.Path)
+		}
+	}
+}
+
+func TestScanDoesNotRecurseIntoSymlinkedDirectories(t *testing.T) {
+	fsys := fstest.MapFS{
+		"top.go":            &fstest.MapFile{Data: []byte("a")},
+		"real/nested.go":    &fstest.MapFile{Data: []byte("b")},
+		"loop":              &fstest.MapFile{Mode: fs.ModeSymlink | fs.ModeDir},
+		"loop/unreached.go": &fstest.MapFile{Data: []byte("c")},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	for _, f := range files {
+		if f.Path == "loop/unreached.go" {
+			t.Fatalf("expected Scan not to recurse into symlinked directory %q, got %v", "loop", files)
+		}
+	}
+}
+
+func TestScanTypeLMatchesSymlinkedDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"real/nested.go": &fstest.MapFile{Data: []byte("b")},
+		"loop":           &fstest.MapFile{Mode: fs.ModeSymlink | fs.ModeDir},
+	}
+
+	files, err := Scan(fsys, []string{"."}, ScanOptions{Filter: typePredicate('l')})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "loop" {
+		t.Fatalf("expected -type l to match the symlinked directory, got %v", files)
+	}
+}
+
+func buildTree(numDirs, filesPerDir int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for d := 0; d < numDirs; d++ {
+		for f := 0; f < filesPerDir; f++ {
+			fsys[fmt.Sprintf("dir%d/file%d.go", d, f)] = &fstest.MapFile{Data: []byte("x")}
+		}
+	}
+	return fsys
+}
+
+func BenchmarkScan(b *testing.B) {
+	fsys := buildTree(200, 50)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Scan(fsys, []string{"."}, ScanOptions{Filter: suffixPredicate([]string{".go"})}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
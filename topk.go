@@ -0,0 +1,117 @@
+package main
+
+import (
+	"container/heap"
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// sortByLess sorts files in place so that less(files[i], files[j]) holds
+// for every i < j.
+func sortByLess(files []FileInfo, less func(a, b FileInfo) bool) {
+	sort.Slice(files, func(i, j int) bool { return less(files[i], files[j]) })
+}
+
+// sortLess returns the comparator for the CLI's mutually exclusive
+// -o/-l/-s sort flags: oldest-first, largest-first, or smallest-first.
+// With none set, it returns the default newest-first comparator.
+func sortLess(oldest, largest, smallest bool) func(a, b FileInfo) bool {
+	switch {
+	case oldest:
+		return func(a, b FileInfo) bool { return a.ModTime.Before(b.ModTime) }
+	case largest:
+		return func(a, b FileInfo) bool { return a.Size > b.Size }
+	case smallest:
+		return func(a, b FileInfo) bool { return a.Size < b.Size }
+	default:
+		return func(a, b FileInfo) bool { return a.ModTime.After(b.ModTime) }
+	}
+}
+
+// topK returns the k files that sort first under less, without fully
+// sorting files. It costs O(N log K) time and O(K) extra memory by keeping
+// only a K-sized min-heap of the best candidates seen so far, instead of the
+// O(N) memory and O(N log N) time of sorting everything and truncating.
+func topK(files []FileInfo, k int, less func(a, b FileInfo) bool) []FileInfo {
+	if k <= 0 {
+		return nil
+	}
+
+	h := &fileHeap{less: less}
+	for _, f := range files {
+		if h.Len() < k {
+			heap.Push(h, f)
+			continue
+		}
+		if less(f, h.files[0]) {
+			h.files[0] = f
+			heap.Fix(h, 0)
+		}
+	}
+
+	result := h.files
+	sortByLess(result, less)
+	return result
+}
+
+// ScanTopK walks roots like Scan, but keeps only the k files that sort
+// first under less, feeding a bounded heap during the walk instead of
+// collecting every match into a slice first. Memory stays O(k) regardless
+// of how many files the walk matches, and the result comes back sorted.
+func ScanTopK(fsys fs.FS, roots []string, opts ScanOptions, k int, less func(a, b FileInfo) bool) ([]FileInfo, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	var mu sync.Mutex
+	h := &fileHeap{less: less}
+
+	err := scanMatches(fsys, roots, opts, func(p string, info fs.FileInfo) {
+		f := FileInfo{Path: p, ModTime: info.ModTime(), Size: info.Size()}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if h.Len() < k {
+			heap.Push(h, f)
+			return
+		}
+		if less(f, h.files[0]) {
+			h.files[0] = f
+			heap.Fix(h, 0)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := h.files
+	sortByLess(result, less)
+	return result, nil
+}
+
+// fileHeap is a container/heap min-heap over FileInfo, ordered so its root
+// is the worst element under less (the first one to evict when a better
+// candidate arrives).
+type fileHeap struct {
+	files []FileInfo
+	less  func(a, b FileInfo) bool
+}
+
+func (h *fileHeap) Len() int { return len(h.files) }
+
+// Less reports whether i is "smaller" in heap terms, i.e. worse under the
+// caller's less, so the heap root is always the current worst kept item.
+func (h *fileHeap) Less(i, j int) bool { return h.less(h.files[j], h.files[i]) }
+
+func (h *fileHeap) Swap(i, j int) { h.files[i], h.files[j] = h.files[j], h.files[i] }
+
+func (h *fileHeap) Push(x any) { h.files = append(h.files, x.(FileInfo)) }
+
+func (h *fileHeap) Pop() any {
+	old := h.files
+	n := len(old)
+	item := old[n-1]
+	h.files = old[:n-1]
+	return item
+}
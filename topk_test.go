@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func syntheticTree(numDirs, filesPerDir int) fstest.MapFS {
+	now := time.Now()
+	fsys := fstest.MapFS{}
+	i := 0
+	for d := 0; d < numDirs; d++ {
+		for f := 0; f < filesPerDir; f++ {
+			fsys[fmt.Sprintf("dir%d/file%d.go", d, f)] = &fstest.MapFile{
+				Data:    make([]byte, (i*37+11)%997),
+				ModTime: now.Add(-time.Duration(i) * time.Minute),
+			}
+			i++
+		}
+	}
+	return fsys
+}
+
+func syntheticFiles(n int) []FileInfo {
+	now := time.Now()
+	files := make([]FileInfo, n)
+	for i := 0; i < n; i++ {
+		files[i] = FileInfo{
+			Path:    fmt.Sprintf("file%d.go", i),
+			ModTime: now.Add(-time.Duration(i) * time.Minute),
+			Size:    int64((i*37 + 11) % 997),
+		}
+	}
+	return files
+}
+
+func TestTopKMatchesSortedThenTruncated(t *testing.T) {
+	files := syntheticFiles(200)
+
+	for _, mode := range []struct {
+		name string
+		less func(a, b FileInfo) bool
+	}{
+		{"newest", sortLess(false, false, false)},
+		{"oldest", sortLess(true, false, false)},
+		{"largest", sortLess(false, true, false)},
+		{"smallest", sortLess(false, false, true)},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			want := make([]FileInfo, len(files))
+			copy(want, files)
+			sortByLess(want, mode.less)
+			want = want[:10]
+
+			got := make([]FileInfo, len(files))
+			copy(got, files)
+			got = topK(got, 10, mode.less)
+
+			if len(got) != len(want) {
+				t.Fatalf("expected %d results, got %d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i].Path != want[i].Path {
+					t.Errorf("index %d: expected %s, got %s", i, want[i].Path, got[i].Path)
+				}
+			}
+		})
+	}
+}
+
+func TestTopKLargerThanInput(t *testing.T) {
+	files := syntheticFiles(5)
+	got := topK(files, 50, sortLess(false, false, false))
+	if len(got) != 5 {
+		t.Fatalf("expected 5 results when k exceeds input size, got %d", len(got))
+	}
+}
+
+func TestScanTopKMatchesSortedThenTruncated(t *testing.T) {
+	fsys := syntheticTree(10, 20)
+	less := sortLess(false, false, false)
+
+	all, err := Scan(fsys, []string{"."}, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	sortByLess(all, less)
+	want := all[:10]
+
+	got, err := ScanTopK(fsys, []string{"."}, ScanOptions{}, 10, less)
+	if err != nil {
+		t.Fatalf("ScanTopK: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Path != want[i].Path {
+			t.Errorf("index %d: expected %s, got %s", i, want[i].Path, got[i].Path)
+		}
+	}
+}
+
+func TestScanTopKZero(t *testing.T) {
+	fsys := fstest.MapFS{"a.go": &fstest.MapFile{Data: []byte("a")}}
+	got, err := ScanTopK(fsys, []string{"."}, ScanOptions{}, 0, sortLess(false, false, false))
+	if err != nil {
+		t.Fatalf("ScanTopK: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no results when k=0, got %v", got)
+	}
+}